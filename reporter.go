@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CommitSummary is the structured representation of a single commit, shared across the JSON,
+// Markdown and YAML reporters.
+type CommitSummary struct {
+	SHA            string    `json:"sha" yaml:"sha"`
+	ShortSHA       string    `json:"short_sha" yaml:"short_sha"`
+	AuthorName     string    `json:"author_name" yaml:"author_name"`
+	AuthorEmail    string    `json:"author_email" yaml:"author_email"`
+	AuthorDate     time.Time `json:"author_date" yaml:"author_date"`
+	CommitterName  string    `json:"committer_name" yaml:"committer_name"`
+	CommitterEmail string    `json:"committer_email" yaml:"committer_email"`
+	CommitDate     time.Time `json:"commit_date" yaml:"commit_date"`
+	Subject        string    `json:"subject" yaml:"subject"`
+	Body           string    `json:"body" yaml:"body"`
+	Parents        []string  `json:"parents" yaml:"parents"`
+}
+
+// RefReport groups the commits found under a single ref (e.g. a branch or remote-tracking
+// branch) within a repository.
+type RefReport struct {
+	Ref     string          `json:"ref" yaml:"ref"`
+	Commits []CommitSummary `json:"commits" yaml:"commits"`
+}
+
+// RepoReport groups the ref reports found for a single repository. By default this contains a
+// single RefReport for HEAD, but the --branches, --remotes and --all flags can expand it to one
+// entry per ref walked.
+type RepoReport struct {
+	Repo string      `json:"repo" yaml:"repo"`
+	Refs []RefReport `json:"refs" yaml:"refs"`
+}
+
+// Reporter renders a set of repository commit reports to w in a specific output format.
+type Reporter interface {
+	Report(w io.Writer, reports []RepoReport) error
+}
+
+// NewReporter returns the Reporter implementation for the given format name. The short flag is
+// only honoured by the text format, where it condenses each commit down to its subject line.
+func NewReporter(format string, short bool) (Reporter, error) {
+	switch format {
+	case "", "text":
+		return &TextReporter{Short: short}, nil
+	case "json":
+		return &JSONReporter{}, nil
+	case "markdown":
+		return &MarkdownReporter{}, nil
+	case "yaml":
+		return &YAMLReporter{}, nil
+	case "html":
+		return &HTMLReporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, must be one of: text, json, markdown, yaml, html", format)
+	}
+}
+
+// TextReporter renders reports in the original human-readable format of this tool, one repo per
+// section with an indented list of commits.
+type TextReporter struct {
+	// Short displays only the subject line of each commit when set.
+	Short bool
+}
+
+func (t *TextReporter) Report(w io.Writer, reports []RepoReport) error {
+	for _, report := range reports {
+		fmt.Fprintf(w, "%s\n", report.Repo)
+
+		empty := true
+		for _, refReport := range report.Refs {
+			if len(refReport.Commits) > 0 {
+				empty = false
+			}
+
+			// Only print the ref as a sub-heading when there is more than one, so that the
+			// default HEAD-only case looks exactly as it did before ref grouping existed.
+			if len(report.Refs) > 1 {
+				fmt.Fprintf(w, "\t%s\n", refReport.Ref)
+			}
+
+			for _, c := range refReport.Commits {
+				indent := "\t"
+				if len(report.Refs) > 1 {
+					indent = "\t\t"
+				}
+				if t.Short || c.Body == "" {
+					fmt.Fprintf(w, "%s%s\n", indent, c.Subject)
+				} else {
+					fmt.Fprintf(w, "%s%s\n%s%s\n", indent, c.Subject, indent, c.Body)
+				}
+			}
+		}
+
+		if empty {
+			fmt.Fprintf(w, "\tThere are no messages for this directory.\n")
+		}
+
+		// Simple newline before the next entry.
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// JSONReporter renders reports as a single JSON array of RepoReport, suitable for piping into
+// other tooling.
+type JSONReporter struct{}
+
+func (j *JSONReporter) Report(w io.Writer, reports []RepoReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
+
+// YAMLReporter renders reports as a single YAML document containing a list of RepoReport.
+type YAMLReporter struct{}
+
+func (y *YAMLReporter) Report(w io.Writer, reports []RepoReport) error {
+	enc := yaml.NewEncoder(w)
+	defer enc.Close()
+	return enc.Encode(reports)
+}
+
+// MarkdownReporter renders reports as one Markdown section per repo, with a bulleted list of
+// commit subjects tagged by their short SHA. This is intended to be pasted directly into a
+// daily-standup note, or piped into something that consumes Markdown.
+type MarkdownReporter struct{}
+
+func (m *MarkdownReporter) Report(w io.Writer, reports []RepoReport) error {
+	for _, report := range reports {
+		fmt.Fprintf(w, "## %s\n\n", report.Repo)
+
+		empty := true
+		for _, refReport := range report.Refs {
+			if len(refReport.Commits) == 0 {
+				continue
+			}
+			empty = false
+
+			if len(report.Refs) > 1 {
+				fmt.Fprintf(w, "### %s\n\n", refReport.Ref)
+			}
+
+			for _, c := range refReport.Commits {
+				fmt.Fprintf(w, "- `%s` %s\n", c.ShortSHA, c.Subject)
+			}
+			fmt.Fprintln(w)
+		}
+
+		if empty {
+			fmt.Fprintf(w, "_There are no messages for this directory._\n\n")
+		}
+	}
+	return nil
+}
+
+// HTMLReporter renders reports as a standalone HTML page, one section per repo, suitable for
+// serving directly from the "today serve" HTTP daemon.
+type HTMLReporter struct{}
+
+func (h *HTMLReporter) Report(w io.Writer, reports []RepoReport) error {
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"><title>today</title></head>\n<body>\n")
+
+	for _, report := range reports {
+		fmt.Fprintf(w, "<h2>%s</h2>\n", html.EscapeString(report.Repo))
+
+		empty := true
+		for _, refReport := range report.Refs {
+			if len(refReport.Commits) == 0 {
+				continue
+			}
+			empty = false
+
+			if len(report.Refs) > 1 {
+				fmt.Fprintf(w, "<h3>%s</h3>\n", html.EscapeString(refReport.Ref))
+			}
+
+			fmt.Fprintf(w, "<ul>\n")
+			for _, c := range refReport.Commits {
+				fmt.Fprintf(w, "<li><code>%s</code> %s</li>\n", html.EscapeString(c.ShortSHA), html.EscapeString(c.Subject))
+			}
+			fmt.Fprintf(w, "</ul>\n")
+		}
+
+		if empty {
+			fmt.Fprintf(w, "<p><em>There are no messages for this directory.</em></p>\n")
+		}
+	}
+
+	fmt.Fprintf(w, "</body>\n</html>\n")
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextReporterNonShortRendersMultiLineBodyWithoutBlankSeparator(t *testing.T) {
+	reports := []RepoReport{
+		{
+			Repo: "today",
+			Refs: []RefReport{
+				{
+					Ref: "main",
+					Commits: []CommitSummary{
+						{Subject: "subject", Body: "body line one\nbody line two"},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	reporter := &TextReporter{Short: false}
+	assert.Nil(t, reporter.Report(&buf, reports))
+
+	// A single ref is grouped under HEAD-only output, so commits are indented once and the body
+	// immediately follows the subject with no stray tab-only line from a leading blank separator.
+	assert.Equal(t, "today\n\tsubject\n\tbody line one\nbody line two\n\n", buf.String())
+}
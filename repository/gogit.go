@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// GoGitRepo implements Repository using the go-git library, which is the default backend since
+// it requires no external git binary.
+//
+// today's gogit CLI backend does not yet drive commits through this type: it needs multi-ref,
+// path, grep, merge and until filtering that Filter does not expose, so it talks to go-git
+// directly (see getRepositories in the root package). GoGitRepo is exercised by the shared
+// backend test suite and is the landing spot for that code once Filter grows those fields.
+type GoGitRepo struct {
+	repo *git.Repository
+}
+
+// OpenGoGitRepo opens the git repository at dir using go-git.
+func OpenGoGitRepo(dir string) (*GoGitRepo, error) {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &GoGitRepo{repo: repo}, nil
+}
+
+// Raw returns the underlying go-git repository, for callers which still need go-git specific
+// behaviour that has not yet been migrated onto the Repository interface.
+func (g *GoGitRepo) Raw() *git.Repository {
+	return g.repo
+}
+
+func (g *GoGitRepo) Head() (Ref, error) {
+	ref, err := g.repo.Head()
+	if err != nil {
+		return Ref{}, err
+	}
+	return Ref{Name: ref.Name().Short(), Hash: ref.Hash().String()}, nil
+}
+
+func (g *GoGitRepo) Refs() ([]Ref, error) {
+	iter, err := g.repo.References()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var refs []Ref
+	err = iter.ForEach(func(r *plumbing.Reference) error {
+		if r.Type() != plumbing.HashReference {
+			return nil
+		}
+
+		name := r.Name()
+		if name.IsBranch() || name.IsRemote() {
+			refs = append(refs, Ref{Name: name.Short(), Hash: r.Hash().String()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refs, nil
+}
+
+func (g *GoGitRepo) LogSince(since time.Time, filter Filter) ([]Commit, error) {
+	head, err := g.repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	cIter, err := g.repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	err = cIter.ForEach(func(c *object.Commit) error {
+		if c.Author.When.Before(since) {
+			return storer.ErrStop
+		}
+
+		if filter.Author != "" && !strings.Contains(c.Author.Name, filter.Author) {
+			return nil
+		}
+
+		commits = append(commits, toCommit(c))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commits, nil
+}
+
+// toCommit converts a go-git commit object into the backend-agnostic Commit shape.
+func toCommit(c *object.Commit) Commit {
+	subject, body, _ := strings.Cut(c.Message, "\n")
+
+	parents := make([]string, 0, len(c.ParentHashes))
+	for _, p := range c.ParentHashes {
+		parents = append(parents, p.String())
+	}
+
+	return Commit{
+		SHA:            c.Hash.String(),
+		ShortSHA:       c.Hash.String()[:7],
+		AuthorName:     c.Author.Name,
+		AuthorEmail:    c.Author.Email,
+		AuthorDate:     c.Author.When.UTC(),
+		CommitterName:  c.Committer.Name,
+		CommitterEmail: c.Committer.Email,
+		CommitDate:     c.Committer.When.UTC(),
+		Subject:        subject,
+		Body:           body,
+		Parents:        parents,
+	}
+}
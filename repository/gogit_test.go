@@ -0,0 +1,13 @@
+package repository
+
+import "testing"
+
+func TestGoGitRepoSuite(t *testing.T) {
+	RunSuite(t, func(t *testing.T, dir string) Repository {
+		repo, err := OpenGoGitRepo(dir)
+		if err != nil {
+			t.Fatalf("OpenGoGitRepo: %s", err)
+		}
+		return repo
+	})
+}
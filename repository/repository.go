@@ -0,0 +1,49 @@
+// Package repository defines a backend-agnostic view of a single git repository, so that today
+// can read commit history via either the go-git library or the system git binary.
+package repository
+
+import "time"
+
+// Commit is the backend-agnostic representation of a single commit.
+type Commit struct {
+	SHA            string
+	ShortSHA       string
+	AuthorName     string
+	AuthorEmail    string
+	AuthorDate     time.Time
+	CommitterName  string
+	CommitterEmail string
+	CommitDate     time.Time
+	Subject        string
+	Body           string
+	Parents        []string
+}
+
+// Ref is a named reference within a repository, such as a local branch or remote-tracking
+// branch.
+type Ref struct {
+	Name string
+	Hash string
+}
+
+// Filter narrows down the commits returned by LogSince. It is expected to grow further fields
+// (path, message pattern, merge/no-merge) as today gains richer filtering.
+type Filter struct {
+	// Author is a 'contains' match against the commit author's name. An empty string matches
+	// every author.
+	Author string
+}
+
+// Repository is implemented by each supported backend (go-git, shell git) for reading commit
+// history out of a single local repository.
+type Repository interface {
+	// Head returns the ref that HEAD currently points to.
+	Head() (Ref, error)
+
+	// Refs returns every local branch and remote-tracking ref in the repository.
+	Refs() ([]Ref, error)
+
+	// LogSince returns every commit reachable from HEAD, committed at or after since, that
+	// matches filter. Commits are returned newest-first.
+	LogSince(since time.Time, filter Filter) ([]Commit, error)
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// logFieldSep and logRecordSep delimit fields within, and records between, the commits emitted
+// by ShellGitRepo.LogSince's --pretty=format. They are inserted via git's %x<hex> escape so that
+// they never collide with legitimate commit content.
+const (
+	logFieldSep  = "\x1f"
+	logRecordSep = "\x1e"
+)
+
+// ShellGitRepo implements Repository by shelling out to the system git binary. This is intended
+// for repositories large enough, or using features novel enough (partial clone, sparse
+// checkouts), that go-git is too slow or cannot open them.
+type ShellGitRepo struct {
+	dir string
+}
+
+// NewShellGitRepo returns a ShellGitRepo rooted at dir. Unlike OpenGoGitRepo, this does not
+// validate that dir is a git repository up front; that is deferred to the first git invocation.
+func NewShellGitRepo(dir string) *ShellGitRepo {
+	return &ShellGitRepo{dir: dir}
+}
+
+// run executes git with the given arguments inside s.dir and returns its trimmed stdout.
+func (s *ShellGitRepo) run(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = s.dir
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return string(out), nil
+}
+
+func (s *ShellGitRepo) Head() (Ref, error) {
+	name, err := s.run("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return Ref{}, err
+	}
+
+	hash, err := s.run("rev-parse", "HEAD")
+	if err != nil {
+		return Ref{}, err
+	}
+
+	return Ref{Name: strings.TrimSpace(name), Hash: strings.TrimSpace(hash)}, nil
+}
+
+func (s *ShellGitRepo) Refs() ([]Ref, error) {
+	out, err := s.run("for-each-ref", "--format=%(refname:short) %(objectname)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Ref
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		refs = append(refs, Ref{Name: fields[0], Hash: fields[1]})
+	}
+
+	return refs, nil
+}
+
+func (s *ShellGitRepo) LogSince(since time.Time, filter Filter) ([]Commit, error) {
+	format := strings.Join([]string{"%H", "%h", "%an", "%ae", "%aI", "%cn", "%ce", "%cI", "%P", "%B"}, logFieldSep) + logRecordSep
+
+	args := []string{"log", "--since=" + since.Format(time.RFC3339), "--pretty=format:" + format}
+	if filter.Author != "" {
+		args = append(args, "--author="+filter.Author)
+	}
+
+	out, err := s.run(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, logRecordSep) {
+		record = strings.Trim(record, "\n")
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, logFieldSep, 10)
+		if len(fields) != 10 {
+			continue
+		}
+
+		authorDate, _ := time.Parse(time.RFC3339, fields[4])
+		commitDate, _ := time.Parse(time.RFC3339, fields[7])
+
+		var parents []string
+		if fields[8] != "" {
+			parents = strings.Fields(fields[8])
+		}
+
+		subject, body, _ := strings.Cut(fields[9], "\n")
+
+		commits = append(commits, Commit{
+			SHA:            fields[0],
+			ShortSHA:       fields[1],
+			AuthorName:     fields[2],
+			AuthorEmail:    fields[3],
+			AuthorDate:     authorDate.UTC(),
+			CommitterName:  fields[5],
+			CommitterEmail: fields[6],
+			CommitDate:     commitDate.UTC(),
+			Subject:        subject,
+			Body:           body,
+			Parents:        parents,
+		})
+	}
+
+	return commits, nil
+}
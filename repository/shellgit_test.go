@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestShellGitRepoSuite(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	RunSuite(t, func(t *testing.T, dir string) Repository {
+		return NewShellGitRepo(dir)
+	})
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFixture lays out a repository on disk with a root commit, a second commit on the default
+// branch, and a third commit on a "feature" branch built on top of it. It is built with go-git
+// directly since the resulting .git directory is just as readable by the shell git backend.
+func newFixture(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	repo, err := git.PlainInit(dir, false)
+	assert.Nil(t, err)
+
+	w, err := repo.Worktree()
+	assert.Nil(t, err)
+
+	commitAt := func(when time.Duration) *git.CommitOptions {
+		return &git.CommitOptions{
+			Author: &object.Signature{
+				Name:  "Fixture User",
+				Email: "fixture@example.com",
+				When:  time.Now().UTC().Add(when),
+			},
+			AllowEmptyCommits: true,
+		}
+	}
+
+	_, err = w.Commit("root", commitAt(-2*time.Hour))
+	assert.Nil(t, err)
+
+	_, err = w.Commit("on main", commitAt(-30*time.Minute))
+	assert.Nil(t, err)
+
+	err = w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	assert.Nil(t, err)
+
+	_, err = w.Commit("on feature", commitAt(-15*time.Minute))
+	assert.Nil(t, err)
+
+	err = w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")})
+	assert.Nil(t, err)
+
+	return dir
+}
+
+// RunSuite exercises the Repository contract against the implementation returned by newRepo for
+// a fixture repository it creates in a temp directory. Every Repository implementation must pass
+// this suite unchanged, à la git-bug's repo_testing.go.
+func RunSuite(t *testing.T, newRepo func(t *testing.T, dir string) Repository) {
+	t.Run("Head", func(t *testing.T) {
+		dir := newFixture(t)
+		repo := newRepo(t, dir)
+
+		head, err := repo.Head()
+		assert.Nil(t, err)
+		assert.Equal(t, "master", head.Name)
+		assert.Len(t, head.Hash, 40)
+	})
+
+	t.Run("Refs", func(t *testing.T) {
+		dir := newFixture(t)
+		repo := newRepo(t, dir)
+
+		refs, err := repo.Refs()
+		assert.Nil(t, err)
+
+		var names []string
+		for _, ref := range refs {
+			names = append(names, ref.Name)
+		}
+		assert.Contains(t, names, "master")
+		assert.Contains(t, names, "feature")
+	})
+
+	t.Run("LogSinceExcludesOlderCommits", func(t *testing.T) {
+		dir := newFixture(t)
+		repo := newRepo(t, dir)
+
+		commits, err := repo.LogSince(time.Now().UTC().Add(-time.Hour), Filter{})
+		assert.Nil(t, err)
+
+		var subjects []string
+		for _, c := range commits {
+			subjects = append(subjects, c.Subject)
+		}
+		assert.Contains(t, subjects, "on main")
+		assert.NotContains(t, subjects, "root")
+	})
+
+	t.Run("LogSinceFiltersByAuthor", func(t *testing.T) {
+		dir := newFixture(t)
+		repo := newRepo(t, dir)
+
+		commits, err := repo.LogSince(time.Now().UTC().Add(-3*time.Hour), Filter{Author: "nobody"})
+		assert.Nil(t, err)
+		assert.Empty(t, commits)
+	})
+
+	t.Run("LogSincePopulatesCommitFields", func(t *testing.T) {
+		dir := newFixture(t)
+		repo := newRepo(t, dir)
+
+		commits, err := repo.LogSince(time.Now().UTC().Add(-time.Hour), Filter{})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, commits)
+
+		c := commits[0]
+		assert.Len(t, c.SHA, 40)
+		assert.Len(t, c.ShortSHA, 7)
+		assert.Equal(t, "Fixture User", c.AuthorName)
+		assert.Equal(t, "fixture@example.com", c.AuthorEmail)
+		assert.False(t, c.AuthorDate.IsZero())
+	})
+}
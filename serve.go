@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// ServeConfig is the YAML configuration consumed by `today serve`, listing the repositories to
+// keep open and how often they should be re-fetched in the background.
+type ServeConfig struct {
+	Repos []string `yaml:"repos"`
+
+	// PollInterval controls how often every repository is re-opened to pick up commits fetched
+	// out-of-band, e.g. "5m". Defaults to 5m when unset.
+	PollInterval string `yaml:"poll_interval"`
+}
+
+// loadServeConfig reads and parses a ServeConfig from the YAML file at path.
+func loadServeConfig(path string) (*ServeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg ServeConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// server keeps a set of repositories open across requests, refreshing them on a background timer
+// so that reports reflect newly fetched commits without reopening on every request.
+type server struct {
+	mu        sync.RWMutex
+	dirs      []string
+	dirToRepo map[string]*git.Repository
+}
+
+// newServer opens every directory in dirs, failing fast if any of them is not a valid git repository.
+func newServer(dirs []string) (*server, error) {
+	repos, err := getRepositories(dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	dirToRepo := make(map[string]*git.Repository, len(dirs))
+	for i, dir := range dirs {
+		dirToRepo[dir] = repos[i]
+	}
+
+	return &server{dirs: dirs, dirToRepo: dirToRepo}, nil
+}
+
+// poll refreshes every repository on interval until ctx is cancelled.
+func (s *server) poll(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refresh()
+		}
+	}
+}
+
+// refresh re-opens every known repository, picking up any commits fetched since it was last opened.
+func (s *server) refresh() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for dir := range s.dirToRepo {
+		repo, err := openGitDir(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "today serve: failed to refresh %s: %s\n", dir, err)
+			continue
+		}
+		s.dirToRepo[dir] = repo
+	}
+}
+
+// snapshot returns the configured directory order alongside a copy of the current dir-to-repo
+// map, so that a request can walk its repositories in a stable order without holding the lock for
+// the duration of the scan.
+func (s *server) snapshot() ([]string, map[string]*git.Repository) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	dirs := make([]string, len(s.dirs))
+	copy(dirs, s.dirs)
+
+	dirToRepo := make(map[string]*git.Repository, len(s.dirToRepo))
+	for dir, repo := range s.dirToRepo {
+		dirToRepo[dir] = repo
+	}
+	return dirs, dirToRepo
+}
+
+// handleReport serves GET /report, aggregating commit reports across every configured repository
+// using the same CommitFilter and Reporter machinery as the CLI. Supported query parameters are
+// "since" (a duration, default 24h), "author" and "format" (text, json, markdown, yaml or html).
+func (s *server) handleReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	since := 24 * time.Hour
+	if v := query.Get("since"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid since: %s", err), http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	format := query.Get("format")
+	reporter, err := NewReporter(format, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter := CommitFilter{
+		Author: query.Get("author"),
+		Since:  since,
+	}
+
+	dirs, dirToRepo := s.snapshot()
+	reports, err := getCommitReports(dirs, dirToRepo, filter, "", false, false, runtime.NumCPU())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch format {
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	case "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	case "yaml":
+		w.Header().Set("Content-Type", "application/yaml")
+	case "", "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	default:
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	if err := reporter.Report(w, reports); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// handleHealthz serves GET /healthz, used by orchestrators to check whether the daemon is up.
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// runServe implements the `today serve` subcommand: it loads a ServeConfig, opens every listed
+// repository, and serves aggregated commit reports over HTTP until interrupted.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("http", ":8080", "address to listen on")
+	configPath := fs.String("config", "", "path to a YAML config file listing repositories to serve")
+	fs.Parse(args)
+
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "Missing mandatory flag: --config")
+		os.Exit(1)
+	}
+
+	cfg, err := loadServeConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := validatePaths(cfg.Repos); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	srv, err := newServer(cfg.Repos)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	interval := 5 * time.Minute
+	if cfg.PollInterval != "" {
+		interval, err = time.ParseDuration(cfg.PollInterval)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid poll_interval: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go srv.poll(ctx, interval)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handleHealthz)
+	mux.HandleFunc("/report", srv.handleReport)
+
+	httpServer := &http.Server{Addr: *addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "today serve: shutdown error: %s\n", err)
+		}
+	}()
+
+	fmt.Printf("today serve: listening on %s\n", *addr)
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadServeConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "repos.yaml")
+	assert.Nil(t, os.WriteFile(configPath, []byte("repos:\n  - "+currentDir+"\npoll_interval: 30s\n"), 0o644))
+
+	cfg, err := loadServeConfig(configPath)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{currentDir}, cfg.Repos)
+	assert.Equal(t, "30s", cfg.PollInterval)
+}
+
+func TestHandleHealthz(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+
+	handleHealthz(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok\n", rec.Body.String())
+}
+
+func TestHandleReportReturnsJSON(t *testing.T) {
+	srv, err := newServer([]string{currentDir})
+	assert.Nil(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/report?since=720h&format=json", nil)
+	rec := httptest.NewRecorder()
+
+	srv.handleReport(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "\"repo\"")
+}
+
+func TestHandleReportRejectsInvalidSince(t *testing.T) {
+	srv, err := newServer([]string{currentDir})
+	assert.Nil(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/report?since=notaduration", nil)
+	rec := httptest.NewRecorder()
+
+	srv.handleReport(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
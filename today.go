@@ -3,14 +3,22 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/jdockerty/today/repository"
+	"golang.org/x/sync/errgroup"
 )
 
 // Since is a flag used to control the amount of time to look back in a repository for commits.
@@ -24,6 +32,63 @@ var short bool
 // Author is a 'contains' match on the author of a commit. For example, searching for 'John' will display all commits by the author name '*John*'.
 var author string
 
+// format selects the Reporter used to render output, one of "text", "json", "markdown" or "yaml".
+var format string
+
+// branches is a comma separated list of local branch names to walk, in addition to HEAD. A
+// single "*" matches every local branch.
+var branches string
+
+// remotes includes refs/remotes/* branches alongside any local branches being walked.
+var remotes bool
+
+// all walks every local and remote-tracking branch in the repository.
+var all bool
+
+// backend selects how each directory is read, one of "auto", "gogit" or "shell". "shell" drives
+// the repository.Repository interface via repository.ShellGitRepo; "gogit" drives the go-git
+// library directly rather than through repository.GoGitRepo (see getRepositories). "auto" picks
+// "shell" for repositories whose .git directory is larger than largeRepoThreshold, and "gogit"
+// otherwise.
+var backend string
+
+// largeRepoThreshold is the .git directory size, in bytes, above which --backend=auto prefers the
+// shell git backend over go-git.
+const largeRepoThreshold = 512 * 1024 * 1024 // 512MiB
+
+// jobs bounds how many repositories are scanned concurrently. It defaults to runtime.NumCPU() so
+// that a large set of directories does not serialise on a single core.
+var jobs int
+
+// until is a flag used to control the most recent point in time, relative to now, to check for
+// commits. Combined with since, it lets the window be shifted away from "now", e.g. for producing
+// a report for an earlier day. It defaults to 0, meaning "up to now".
+var until time.Duration
+
+// path only matches commits that touch a file beginning with this pathspec.
+var path string
+
+// grep only matches commits whose message matches this regular expression.
+var grep string
+
+// noMerges excludes merge commits (those with more than one parent).
+var noMerges bool
+
+// mergesOnly restricts results to merge commits (those with more than one parent).
+var mergesOnly bool
+
+// discoverRoot, when set, enables discovery mode: today walks this directory tree looking for git
+// repositories instead of requiring each one to be listed on the command line.
+var discoverRoot string
+
+// maxDepth bounds how many directories below discoverRoot are descended into while discovering
+// repositories. 0, the default, means unlimited.
+var maxDepth int
+
+// exclude is a comma separated list of glob patterns. Any directory encountered during discovery
+// whose base name matches one of them is skipped entirely, along with everything beneath it.
+var exclude string
+
 // validatePaths is used to ensure that only directories that are tracked by git are passed into the application,
 // as these directories are used to track the work which was been done, via commit messages.
 func validatePaths(paths []string) error {
@@ -58,7 +123,70 @@ func openGitDir(dir string) (*git.Repository, error) {
 	return repo, nil
 }
 
+// discoverRepos walks root looking for git repositories, treating any directory containing a
+// .git entry as one, whether it's a directory (a normal repository) or a file (a worktree or
+// submodule). Descent stops as soon as a repository is found, so that a repo's own nested
+// submodules or vendored dependencies aren't reported as separate repositories.
+//
+// maxDepth, if non-zero, bounds how many directories below root are descended into. exclude globs
+// are matched against each directory's base name; a match skips that directory, and everything
+// beneath it, entirely.
+func discoverRepos(root string, maxDepth int, exclude []string) ([]string, error) {
+
+	var repos []string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != root {
+			for _, pattern := range exclude {
+				matched, err := filepath.Match(pattern, d.Name())
+				if err != nil {
+					return err
+				}
+				if matched {
+					return fs.SkipDir
+				}
+			}
+		}
+
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+			return fs.SkipDir
+		}
+
+		if maxDepth > 0 {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			if rel != "." && strings.Count(rel, string(os.PathSeparator))+1 >= maxDepth {
+				return fs.SkipDir
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
 // getRepositories will return the git repository definition given a list of directory paths.
+// getRepositories opens dirs as raw go-git repositories for the gogit backend, rather than
+// through repository.GoGitRepo: the Repository interface's Filter has no fields yet for the
+// multi-ref, path, grep, merge and until filtering that resolveRefs/walkCommits implement, so
+// today.go drives go-git directly here and reserves the interface path for the shell backend.
+// GoGitRepo exists to keep the interface honest (it is exercised by the shared backend test
+// suite) and as the landing spot once Filter grows those fields.
 func getRepositories(dirs []string) ([]*git.Repository, error) {
 
 	var repos []*git.Repository
@@ -94,81 +222,465 @@ func getBaseDirectoryName(p string) (string, error) {
 	return filepath.Base(p), nil
 }
 
-// getCommitMessages is used to map together the repository to a list of valid messages, dependent on the flags that were passed.
-func getCommitMessages(dirToRepo map[string]*git.Repository, author string, short bool, since time.Duration) (map[string][]string, error) {
-
-	msgs := make(map[string][]string)
+// matchesAny reports whether name matches any of the given branch patterns, which may contain a
+// single "*" wildcard meaning "match every branch".
+func matchesAny(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if p == "*" || p == name {
+			return true
+		}
+	}
+	return false
+}
 
-	for dir, repo := range dirToRepo {
+// resolveRefs determines which references within repo should be walked, based on the --branches,
+// --remotes and --all flags. When none of these are set, only the current HEAD is walked,
+// matching the tool's original behaviour.
+func resolveRefs(repo *git.Repository, branches string, remotes bool, all bool) ([]*plumbing.Reference, error) {
 
-		sanitisedDir, err := getBaseDirectoryName(dir)
+	if branches == "" && !remotes && !all {
+		head, err := repo.Head()
 		if err != nil {
 			return nil, err
 		}
-		// Initialise map before populating messages.
-		// This largely comes in handy when a directory is passed where there are no messages in the given 'since' range
-		// so it can be displayed as no messages, as opposed to no output whatsoever.
-		msgs[sanitisedDir] = []string{}
+		return []*plumbing.Reference{head}, nil
+	}
+
+	iter, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var branchPatterns []string
+	if branches != "" {
+		branchPatterns = strings.Split(branches, ",")
+	}
+
+	var refs []*plumbing.Reference
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
 
-		ref, err := repo.Head()
-		if err != nil {
-			return nil, err
+		name := ref.Name()
+
+		switch {
+		case all:
+			if name.IsBranch() || name.IsRemote() {
+				refs = append(refs, ref)
+			}
+		case name.IsRemote():
+			if remotes {
+				refs = append(refs, ref)
+			}
+		case name.IsBranch():
+			if matchesAny(name.Short(), branchPatterns) {
+				refs = append(refs, ref)
+			}
 		}
 
-		cIter, err := repo.Log(&git.LogOptions{From: ref.Hash()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// repo.References() does not guarantee iteration order, so sort by short name to keep both
+	// the output's ref grouping and the shared-commit dedup in getCommitReports stable run to run.
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Name().Short() < refs[j].Name().Short() })
+
+	return refs, nil
+}
+
+// CommitFilter narrows down the commits returned by walkCommits. Every non-zero field must match
+// for a commit to be included; an unset field imposes no constraint.
+type CommitFilter struct {
+	// Author is a 'contains' match against the commit author's name.
+	Author string
+
+	// Since and Until bound the commit window to [now-Since, now-Until]. Until defaults to the
+	// zero value, meaning "up to now".
+	Since time.Duration
+	Until time.Duration
+
+	// Path, when set, only matches commits that touch a file path beginning with this pathspec.
+	Path string
+
+	// Grep, when set, only matches commits whose message matches this pattern.
+	Grep *regexp.Regexp
+
+	// Merges and NoMerges restrict results to commits with more than one parent, or exactly one
+	// parent, respectively. Setting both excludes every commit; main guards against this.
+	Merges   bool
+	NoMerges bool
+}
+
+// matches reports whether c satisfies every constraint set on filter, other than the time window,
+// which walkCommits checks separately since it also controls when the scan can stop early.
+func (filter CommitFilter) matches(c *object.Commit) (bool, error) {
+	if filter.Author != "" && !containsAuthor(c, filter.Author) {
+		return false, nil
+	}
+
+	numParents := len(c.ParentHashes)
+	if filter.NoMerges && numParents > 1 {
+		return false, nil
+	}
+	if filter.Merges && numParents <= 1 {
+		return false, nil
+	}
+
+	if filter.Grep != nil && !filter.Grep.MatchString(c.Message) {
+		return false, nil
+	}
+
+	if filter.Path != "" {
+		touched, err := commitTouchesPath(c, filter.Path)
 		if err != nil {
-			return nil, err
+			return false, err
 		}
+		if !touched {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// commitTouchesPath reports whether c's diff against its first parent (or, for a root commit,
+// against an empty tree) contains a file under the pathspec path, matched on a path component
+// boundary rather than a raw string prefix so that --path a does not also match abc.txt.
+func commitTouchesPath(c *object.Commit, path string) (bool, error) {
+	path = strings.TrimSuffix(path, "/")
+
+	stats, err := c.Stats()
+	if err != nil {
+		return false, err
+	}
+
+	for _, stat := range stats {
+		if stat.Name == path || strings.HasPrefix(stat.Name, path+"/") {
+			return true, nil
+		}
+	}
 
-		now := time.Now().UTC()
-		currentCommit, err := cIter.Next()
+	return false, nil
+}
+
+// walkCommits replays the commit history of repo from the given starting hash, returning every
+// commit within filter's [since, until] window that matches its other constraints. Commits are
+// returned newest-first, the same order produced by repo.Log.
+//
+// The full window is scanned rather than stopping at the first commit that fails a filter, since
+// commits are chronological but a filter such as Author or Path may legitimately exclude commits
+// scattered throughout the window.
+func walkCommits(repo *git.Repository, from plumbing.Hash, filter CommitFilter) ([]*object.Commit, error) {
+
+	cIter, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	windowStart := now.Add(-filter.Since)
+	windowEnd := now.Add(-filter.Until)
+
+	var commits []*object.Commit
+	err = cIter.ForEach(func(c *object.Commit) error {
+		commitTime := c.Author.When.UTC()
+
+		// Commits are visited newest-first, so once we pass the start of the window every
+		// earlier commit will too; it is safe to stop here.
+		if commitTime.Before(windowStart) {
+			return storer.ErrStop
+		}
+
+		if commitTime.After(windowEnd) {
+			return nil
+		}
+
+		matched, err := filter.matches(c)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if !matched {
+			return nil
 		}
 
-		commitTime := currentCommit.Author.When.UTC()
+		commits = append(commits, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commits, nil
+}
+
+// dirMessages pairs a sanitised directory name with the commit messages found for it, used to
+// hand results from a getCommitMessages worker back to the collecting goroutine.
+type dirMessages struct {
+	dir  string
+	msgs []string
+}
 
-		// The UTC time of now - the provided 'since' value.
-		// We use time.Add with a negative number to subtract here, rather than time.Sub, so that we produce a time.Time value to compare, not a time.Duration.
-		timeSince := now.Add(-since)
+// getCommitMessages is used to map together the repository to a list of valid messages, dependent on the flags that were passed.
+// Each repository's log is walked by its own worker, up to jobs at a time, since this is the
+// dominant cost when callers pass dozens of directories.
+func getCommitMessages(dirToRepo map[string]*git.Repository, filter CommitFilter, short bool, jobs int) (map[string][]string, error) {
 
-		// Only iterate whilst we meet the criteria of the current commit being before our `since` value.
-		// Once we have reached the commit where this is not the case, we can stop as commits are in chronological order.
-		// Note: We are not accounting for any `--date` manipulation, this will simply use the timestamp it currently has,
-		// meaning that it can stop prematurely if it no longer matches the loop clause.
-		for commitTime.After(timeSince) {
+	results := make(chan dirMessages, len(dirToRepo))
 
-			// Get the next commit ready here so avoid needing to duplicate logic branches
-			// when needing to skip commits.
-			// TODO: Can we tidy this up in an elegant way?
-			nextCommit, err := cIter.Next()
+	g := new(errgroup.Group)
+	g.SetLimit(jobs)
+
+	for dir, repo := range dirToRepo {
+		dir, repo := dir, repo
+		g.Go(func() error {
+			sanitisedDir, err := getBaseDirectoryName(dir)
 			if err != nil {
-				return nil, err
+				return err
 			}
 
-			// Skip commits which do not contain the author name provided
-			if author != "" && !containsAuthor(currentCommit, author) {
-				currentCommit = nextCommit
-				commitTime = currentCommit.Author.When.UTC()
-				continue
+			head, err := repo.Head()
+			if err != nil {
+				return err
 			}
 
-			if short {
-				// Multi-line commit messages span over newlines, taking the text before this is the main message and the rest can be discarded.
-				firstLine, _, _ := strings.Cut(currentCommit.Message, "\n")
-				msgs[dir] = append(msgs[dir], firstLine)
-			} else {
-				msgs[dir] = append(msgs[dir], currentCommit.Message)
+			commits, err := walkCommits(repo, head.Hash(), filter)
+			if err != nil {
+				return err
 			}
 
-			currentCommit = nextCommit
-			commitTime = currentCommit.Author.When.UTC()
-		}
+			// Initialise the slice, even when empty, so that a directory with no messages in the
+			// given 'since' range can be displayed as no messages, as opposed to no output whatsoever.
+			dirMsgs := []string{}
+			for _, commit := range commits {
+				if short {
+					// Multi-line commit messages span over newlines, taking the text before this is the main message and the rest can be discarded.
+					firstLine, _, _ := strings.Cut(commit.Message, "\n")
+					dirMsgs = append(dirMsgs, firstLine)
+				} else {
+					dirMsgs = append(dirMsgs, commit.Message)
+				}
+			}
+
+			results <- dirMessages{dir: sanitisedDir, msgs: dirMsgs}
+			return nil
+		})
+	}
+
+	err := g.Wait()
+	close(results)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make(map[string][]string, len(dirToRepo))
+	for r := range results {
+		msgs[r.dir] = r.msgs
 	}
 
 	return msgs, nil
 }
 
+// resolveBackend returns the concrete backend ("gogit" or "shell") to use for dir, resolving
+// "auto" (and the default empty string) based on the on-disk size of its .git directory.
+func resolveBackend(dir, backend string) (string, error) {
+	switch backend {
+	case "gogit", "shell":
+		return backend, nil
+	case "", "auto":
+		size, err := dirSize(filepath.Join(dir, ".git"))
+		if err != nil {
+			return "", err
+		}
+		if size > largeRepoThreshold {
+			return "shell", nil
+		}
+		return "gogit", nil
+	default:
+		return "", fmt.Errorf("unknown backend %q, must be one of: auto, gogit, shell", backend)
+	}
+}
+
+// dirSize returns the total size, in bytes, of every regular file under dir.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// commitSummaryFromRepository converts a backend-agnostic repository.Commit into the
+// CommitSummary shape used by the Reporters.
+func commitSummaryFromRepository(c repository.Commit) CommitSummary {
+	return CommitSummary{
+		SHA:            c.SHA,
+		ShortSHA:       c.ShortSHA,
+		AuthorName:     c.AuthorName,
+		AuthorEmail:    c.AuthorEmail,
+		AuthorDate:     c.AuthorDate,
+		CommitterName:  c.CommitterName,
+		CommitterEmail: c.CommitterEmail,
+		CommitDate:     c.CommitDate,
+		Subject:        c.Subject,
+		Body:           c.Body,
+		Parents:        c.Parents,
+	}
+}
+
+// getShellCommitReports builds one RepoReport per directory using the shell git backend. It only
+// walks HEAD; --branches, --remotes and --all are not yet supported on this backend. Up to jobs
+// directories are scanned concurrently.
+func getShellCommitReports(dirs []string, author string, since time.Duration, jobs int) ([]RepoReport, error) {
+
+	reports := make([]RepoReport, len(dirs))
+
+	g := new(errgroup.Group)
+	g.SetLimit(jobs)
+
+	for i, dir := range dirs {
+		i, dir := i, dir
+		g.Go(func() error {
+			sanitisedDir, err := getBaseDirectoryName(dir)
+			if err != nil {
+				return err
+			}
+
+			repo := repository.NewShellGitRepo(dir)
+
+			head, err := repo.Head()
+			if err != nil {
+				return err
+			}
+
+			commits, err := repo.LogSince(time.Now().UTC().Add(-since), repository.Filter{Author: author})
+			if err != nil {
+				return err
+			}
+
+			refReport := RefReport{Ref: head.Name, Commits: make([]CommitSummary, 0, len(commits))}
+			for _, c := range commits {
+				refReport.Commits = append(refReport.Commits, commitSummaryFromRepository(c))
+			}
+
+			reports[i] = RepoReport{Repo: sanitisedDir, Refs: []RefReport{refReport}}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// toCommitSummary converts a go-git commit object into the structured form shared by the JSON,
+// Markdown and YAML reporters.
+func toCommitSummary(commit *object.Commit) CommitSummary {
+	subject, body, _ := strings.Cut(commit.Message, "\n")
+	body = strings.TrimLeft(body, "\n")
+
+	parents := make([]string, 0, len(commit.ParentHashes))
+	for _, p := range commit.ParentHashes {
+		parents = append(parents, p.String())
+	}
+
+	return CommitSummary{
+		SHA:            commit.Hash.String(),
+		ShortSHA:       commit.Hash.String()[:7],
+		AuthorName:     commit.Author.Name,
+		AuthorEmail:    commit.Author.Email,
+		AuthorDate:     commit.Author.When.UTC(),
+		CommitterName:  commit.Committer.Name,
+		CommitterEmail: commit.Committer.Email,
+		CommitDate:     commit.Committer.When.UTC(),
+		Subject:        subject,
+		Body:           body,
+		Parents:        parents,
+	}
+}
+
+// getCommitReports collects the full commit metadata for each repository, rather than the plain
+// message text that getCommitMessages produces. This is used by Reporters which need more than a
+// one-line summary, such as the JSON, Markdown and YAML formats.
+//
+// By default only HEAD is walked, but branches, remotes and all extend this to every ref selected
+// by resolveRefs. A commit reachable from more than one ref is only reported against the first ref
+// it is encountered under, so totals across refs do not double count shared history.
+//
+// Each repository is walked by its own worker, up to jobs at a time, but reports are returned in
+// the same order as dirs regardless of which worker finishes first.
+func getCommitReports(dirs []string, dirToRepo map[string]*git.Repository, filter CommitFilter, branches string, remotes bool, all bool, jobs int) ([]RepoReport, error) {
+
+	reports := make([]RepoReport, len(dirs))
+
+	g := new(errgroup.Group)
+	g.SetLimit(jobs)
+
+	for i, dir := range dirs {
+		i, dir := i, dir
+		repo := dirToRepo[dir]
+		g.Go(func() error {
+			sanitisedDir, err := getBaseDirectoryName(dir)
+			if err != nil {
+				return err
+			}
+
+			refs, err := resolveRefs(repo, branches, remotes, all)
+			if err != nil {
+				return err
+			}
+
+			report := RepoReport{Repo: sanitisedDir, Refs: make([]RefReport, 0, len(refs))}
+			seen := make(map[plumbing.Hash]bool)
+
+			for _, ref := range refs {
+				commits, err := walkCommits(repo, ref.Hash(), filter)
+				if err != nil {
+					return err
+				}
+
+				refReport := RefReport{Ref: ref.Name().Short(), Commits: make([]CommitSummary, 0, len(commits))}
+				for _, commit := range commits {
+					if seen[commit.Hash] {
+						continue
+					}
+					seen[commit.Hash] = true
+
+					refReport.Commits = append(refReport.Commits, toCommitSummary(commit))
+				}
+
+				report.Refs = append(report.Refs, refReport)
+			}
+
+			reports[i] = report
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
 func printUsage() {
 	var executableName string
 	fullPath, err := os.Executable()
@@ -184,57 +696,169 @@ func printUsage() {
 
 func main() {
 
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
+	}
+
 	flag.Usage = printUsage
 
 	flag.BoolVar(&short, "short", false, "display the first line of commit messages only")
 	flag.DurationVar(&since, "since", 12*time.Hour, "how far back to check for commits from now")
 	flag.StringVar(&author, "author", "", "display commits from a particular author")
+	flag.StringVar(&format, "format", "text", "output format: text, json, markdown, yaml")
+	flag.StringVar(&branches, "branches", "", "comma separated list of branches to walk in addition to HEAD, or '*' for every branch")
+	flag.BoolVar(&remotes, "remotes", false, "include refs/remotes/* branches")
+	flag.BoolVar(&all, "all", false, "walk every local and remote-tracking branch")
+	flag.StringVar(&backend, "backend", "auto", "repository backend: auto, gogit, shell")
+	flag.IntVar(&jobs, "jobs", runtime.NumCPU(), "number of repositories to scan concurrently")
+	flag.DurationVar(&until, "until", 0, "how far back to stop checking for commits from now, e.g. '24h' to exclude today; defaults to now")
+	flag.StringVar(&path, "path", "", "only include commits touching a file beginning with this path")
+	flag.StringVar(&grep, "grep", "", "only include commits whose message matches this regular expression")
+	flag.BoolVar(&noMerges, "no-merges", false, "exclude merge commits")
+	flag.BoolVar(&mergesOnly, "merges", false, "only include merge commits")
+	flag.StringVar(&discoverRoot, "discover", "", "walk this directory tree for git repositories, instead of listing them as arguments")
+	flag.IntVar(&maxDepth, "max-depth", 0, "maximum directory depth to descend into during --discover; 0 means unlimited")
+	flag.StringVar(&exclude, "exclude", "", "comma separated list of glob patterns to skip during --discover")
 	flag.Parse()
 
-	if flag.NArg() == 0 {
+	reporter, err := NewReporter(format, short)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		printUsage()
+		os.Exit(1)
+	}
+
+	if discoverRoot == "" && flag.NArg() == 0 {
 		fmt.Fprintln(os.Stderr, "Missing mandatory argument: git_directory")
 		printUsage()
 		os.Exit(1)
 	}
 
-	// Directories must be tracked by git so that we can read commit messages and use this
-	// as a guide on work done throughout a time period.
-	err := validatePaths(flag.Args())
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(2)
+	if noMerges && mergesOnly {
+		fmt.Fprintln(os.Stderr, "--no-merges and --merges are mutually exclusive")
+		os.Exit(1)
 	}
 
-	dirs := flag.Args()
+	var grepPattern *regexp.Regexp
+	if grep != "" {
+		grepPattern, err = regexp.Compile(grep)
+		if err != nil {
+			fmt.Printf("invalid --grep pattern: %s\n", err)
+			os.Exit(1)
+		}
+	}
 
-	repos, err := getRepositories(dirs)
-	if err != nil {
-		fmt.Println(err)
-		return
+	filter := CommitFilter{
+		Author:   author,
+		Since:    since,
+		Until:    until,
+		Path:     path,
+		Grep:     grepPattern,
+		Merges:   mergesOnly,
+		NoMerges: noMerges,
+	}
+
+	var dirs []string
+	if discoverRoot != "" {
+		var excludeGlobs []string
+		if exclude != "" {
+			excludeGlobs = strings.Split(exclude, ",")
+		}
+
+		dirs, err = discoverRepos(discoverRoot, maxDepth, excludeGlobs)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if len(dirs) == 0 {
+			fmt.Printf("no git repositories found under %s\n", discoverRoot)
+			os.Exit(1)
+		}
+	} else {
+		// Directories must be tracked by git so that we can read commit messages and use this
+		// as a guide on work done throughout a time period.
+		if err := validatePaths(flag.Args()); err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+
+		dirs = flag.Args()
 	}
 
-	dirToRepo := make(map[string]*git.Repository)
-	for i := 0; i < len(dirs); i++ {
-		dirToRepo[dirs[i]] = repos[i]
+	// Each directory independently resolves its backend, so that --backend=auto can mix the
+	// go-git and shell implementations across a set of repos of differing size. dirBackends
+	// records which list each entry of dirs ended up in, so the two can be merged back into the
+	// original order once both have been reported on.
+	var gogitDirs, shellDirs []string
+	dirBackends := make([]string, len(dirs))
+	for i, dir := range dirs {
+		resolved, err := resolveBackend(dir, backend)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		dirBackends[i] = resolved
+		if resolved == "shell" {
+			shellDirs = append(shellDirs, dir)
+		} else {
+			gogitDirs = append(gogitDirs, dir)
+		}
 	}
 
-	msgs, err := getCommitMessages(dirToRepo, author, short, since)
-	if err != nil {
-		fmt.Println(err)
-		return
+	if len(shellDirs) > 0 && (branches != "" || remotes || all || until != 0 || path != "" || grep != "" || noMerges || mergesOnly) {
+		fmt.Printf("%s: --branches, --remotes, --all, --until, --path, --grep, --no-merges and --merges are not yet supported with the shell backend\n", strings.Join(shellDirs, ", "))
+		os.Exit(1)
 	}
 
-	for dir, commitMsgs := range msgs {
-		fmt.Printf("%s\n", dir)
+	var gogitReports, shellReports []RepoReport
 
-		if len(commitMsgs) == 0 {
-			fmt.Printf("\tThere are no messages for this directory.\n")
+	if len(gogitDirs) > 0 {
+		repos, err := getRepositories(gogitDirs)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		dirToRepo := make(map[string]*git.Repository)
+		for i := 0; i < len(gogitDirs); i++ {
+			dirToRepo[gogitDirs[i]] = repos[i]
 		}
-		for _, msg := range commitMsgs {
-			fmt.Printf("\t%s\n", msg)
+
+		gogitReports, err = getCommitReports(gogitDirs, dirToRepo, filter, branches, remotes, all, jobs)
+		if err != nil {
+			fmt.Println(err)
+			return
 		}
+	}
 
-		// Simple newline before the next entry.
-		fmt.Println()
+	if len(shellDirs) > 0 {
+		var err error
+		shellReports, err = getShellCommitReports(shellDirs, author, since, jobs)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	// Merge the two backends' reports back into the order dirs were originally given in, rather
+	// than grouping all go-git repos ahead of all shell repos or sorting alphabetically.
+	reports := make([]RepoReport, 0, len(dirs))
+	var gogitIdx, shellIdx int
+	for _, backend := range dirBackends {
+		if backend == "shell" {
+			reports = append(reports, shellReports[shellIdx])
+			shellIdx++
+		} else {
+			reports = append(reports, gogitReports[gogitIdx])
+			gogitIdx++
+		}
+	}
+
+	if err := reporter.Report(os.Stdout, reports); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 }